@@ -0,0 +1,51 @@
+// Command vue-gen compiles a component's template HTML into a Go source
+// file implementing its Render function, for use from a //go:generate
+// directive:
+//
+//	//go:generate vue-gen -pkg=myapp -struct=Card -out=card_gen.go card.html
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/ghughes/vue/gen"
+)
+
+func main() {
+	pkg := flag.String("pkg", "", "package name of the generated file")
+	structName := flag.String("struct", "", "component's Go identifier, used to name Render<struct>")
+	out := flag.String("out", "", "output file (defaults to stdout)")
+	noOptimizeStatic := flag.Bool("no-optimize-static", false, "disable collapsing of static markup into single writes")
+	flag.Parse()
+
+	if *pkg == "" || *structName == "" || flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: vue-gen -pkg=... -struct=... [-out=...] template.html")
+		os.Exit(2)
+	}
+
+	tmpl, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := gen.Generate(string(tmpl), gen.Options{
+		Package:          *pkg,
+		Struct:           *structName,
+		NoOptimizeStatic: *noOptimizeStatic,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package expr
+
+// Node is a parsed expression node.
+type Node interface {
+	node()
+}
+
+// Ident is a bare identifier, e.g. "count".
+type Ident struct {
+	Name string
+}
+
+// Literal is a literal number, string, bool or nil value.
+type Literal struct {
+	Value interface{}
+}
+
+// Member is a "." property access, e.g. "user.name".
+type Member struct {
+	Object   Node
+	Property string
+}
+
+// Index is a "[]" property access, e.g. "items[0]".
+type Index struct {
+	Object Node
+	Key    Node
+}
+
+// Call is a function/method call, e.g. "user.name.toUpperCase()".
+type Call struct {
+	Callee Node
+	Args   []Node
+}
+
+// Unary is a prefix unary operation, e.g. "!active".
+type Unary struct {
+	Op string
+	X  Node
+}
+
+// Binary is an infix binary operation, e.g. "count > 0".
+type Binary struct {
+	Op   string
+	X, Y Node
+}
+
+// Ternary is a "cond ? then : else" expression.
+type Ternary struct {
+	Cond, Then, Else Node
+}
+
+func (Ident) node()   {}
+func (Literal) node() {}
+func (Member) node()  {}
+func (Index) node()   {}
+func (Call) node()    {}
+func (Unary) node()   {}
+func (Binary) node()  {}
+func (Ternary) node() {}
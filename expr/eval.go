@@ -0,0 +1,248 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Eval evaluates an already-parsed expression against data, resolving bare
+// identifiers and member access against struct fields and map entries via
+// reflect.
+func Eval(n Node, data map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case Ident:
+		v, ok := data[n.Name]
+		if !ok {
+			return nil, nil
+		}
+		return v, nil
+	case Literal:
+		return n.Value, nil
+	case Member:
+		obj, err := Eval(n.Object, data)
+		if err != nil {
+			return nil, err
+		}
+		return lookup(obj, n.Property)
+	case Index:
+		obj, err := Eval(n.Object, data)
+		if err != nil {
+			return nil, err
+		}
+		key, err := Eval(n.Key, data)
+		if err != nil {
+			return nil, err
+		}
+		return index(obj, key)
+	case Call:
+		return evalCall(n, data)
+	case Unary:
+		x, err := Eval(n.X, data)
+		if err != nil {
+			return nil, err
+		}
+		return evalUnary(n.Op, x)
+	case Binary:
+		return evalBinary(n, data)
+	case Ternary:
+		cond, err := Eval(n.Cond, data)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(cond) {
+			return Eval(n.Then, data)
+		}
+		return Eval(n.Else, data)
+	default:
+		return nil, fmt.Errorf("expr: cannot evaluate %T", n)
+	}
+}
+
+// lookup resolves prop on obj, reading struct fields and map entries.
+func lookup(obj interface{}, prop string) (interface{}, error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		val := v.MapIndex(reflect.ValueOf(prop))
+		if !val.IsValid() {
+			return nil, nil
+		}
+		return val.Interface(), nil
+	case reflect.Struct:
+		val := v.FieldByName(prop)
+		if !val.IsValid() {
+			return nil, nil
+		}
+		return val.Interface(), nil
+	default:
+		return nil, fmt.Errorf("expr: cannot access property %q of %T", prop, obj)
+	}
+}
+
+// index resolves obj[key] for slices, arrays and maps.
+func index(obj, key interface{}) (interface{}, error) {
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, ok := key.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expr: index must be a number, got %T", key)
+		}
+		n := int(i)
+		if n < 0 || n >= v.Len() {
+			return nil, nil
+		}
+		return v.Index(n).Interface(), nil
+	case reflect.Map:
+		val := v.MapIndex(reflect.ValueOf(key))
+		if !val.IsValid() {
+			return nil, nil
+		}
+		return val.Interface(), nil
+	default:
+		return nil, fmt.Errorf("expr: cannot index %T", obj)
+	}
+}
+
+// stringMethods implements the small subset of JS String.prototype methods
+// interpolations commonly rely on.
+var stringMethods = map[string]func(string, []interface{}) interface{}{
+	"toUpperCase": func(s string, _ []interface{}) interface{} { return strings.ToUpper(s) },
+	"toLowerCase": func(s string, _ []interface{}) interface{} { return strings.ToLower(s) },
+	"trim":        func(s string, _ []interface{}) interface{} { return strings.TrimSpace(s) },
+}
+
+// evalCall evaluates a function call. Only method-style calls on a value
+// (e.g. "name.toUpperCase()") are supported.
+func evalCall(call Call, data map[string]interface{}) (interface{}, error) {
+	member, ok := call.Callee.(Member)
+	if !ok {
+		return nil, fmt.Errorf("expr: only method calls are supported")
+	}
+	recv, err := Eval(member.Object, data)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, len(call.Args))
+	for i, a := range call.Args {
+		v, err := Eval(a, data)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	if s, ok := recv.(string); ok {
+		if fn, ok := stringMethods[member.Property]; ok {
+			return fn(s, args), nil
+		}
+	}
+	return nil, fmt.Errorf("expr: unknown method %q on %T", member.Property, recv)
+}
+
+func evalUnary(op string, x interface{}) (interface{}, error) {
+	switch op {
+	case "!":
+		return !truthy(x), nil
+	case "-":
+		n, ok := x.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expr: unary - requires a number, got %T", x)
+		}
+		return -n, nil
+	default:
+		return nil, fmt.Errorf("expr: unknown unary operator %q", op)
+	}
+}
+
+func evalBinary(b Binary, data map[string]interface{}) (interface{}, error) {
+	// && and || short-circuit, so Y is only evaluated when needed.
+	if b.Op == "&&" || b.Op == "||" {
+		x, err := Eval(b.X, data)
+		if err != nil {
+			return nil, err
+		}
+		if b.Op == "&&" && !truthy(x) {
+			return x, nil
+		}
+		if b.Op == "||" && truthy(x) {
+			return x, nil
+		}
+		return Eval(b.Y, data)
+	}
+
+	x, err := Eval(b.X, data)
+	if err != nil {
+		return nil, err
+	}
+	y, err := Eval(b.Y, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.Op {
+	case "==", "===":
+		return reflect.DeepEqual(x, y), nil
+	case "!=", "!==":
+		return !reflect.DeepEqual(x, y), nil
+	}
+
+	xn, xok := x.(float64)
+	yn, yok := y.(float64)
+	if xok && yok {
+		switch b.Op {
+		case "+":
+			return xn + yn, nil
+		case "-":
+			return xn - yn, nil
+		case "*":
+			return xn * yn, nil
+		case "/":
+			return xn / yn, nil
+		case "%":
+			return float64(int(xn) % int(yn)), nil
+		case "<":
+			return xn < yn, nil
+		case "<=":
+			return xn <= yn, nil
+		case ">":
+			return xn > yn, nil
+		case ">=":
+			return xn >= yn, nil
+		}
+	}
+
+	if b.Op == "+" {
+		if xs, ok := x.(string); ok {
+			return xs + fmt.Sprint(y), nil
+		}
+		if ys, ok := y.(string); ok {
+			return fmt.Sprint(x) + ys, nil
+		}
+	}
+
+	return nil, fmt.Errorf("expr: unsupported operator %q for %T and %T", b.Op, x, y)
+}
+
+// truthy reports whether v should be treated as true by v-if/&&/||/!.
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	default:
+		return true
+	}
+}
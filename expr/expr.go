@@ -0,0 +1,20 @@
+// Package expr tokenises, parses and evaluates a small JS-like expression
+// subset (literals, identifiers, member/index access, unary/binary/ternary
+// operators and method calls) against a map[string]interface{} data scope,
+// for use in v-if, v-for, v-bind and text interpolations.
+package expr
+
+// EvalString parses and evaluates src against data in one step.
+func EvalString(src string, data map[string]interface{}) (interface{}, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return Eval(node, data)
+}
+
+// Truthy reports whether v should be treated as true, matching the
+// semantics Eval uses internally for &&, || and unary !.
+func Truthy(v interface{}) bool {
+	return truthy(v)
+}
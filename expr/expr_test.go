@@ -0,0 +1,95 @@
+package expr
+
+import "testing"
+
+func eval(t *testing.T, src string, data map[string]interface{}) interface{} {
+	t.Helper()
+	v, err := EvalString(src, data)
+	if err != nil {
+		t.Fatalf("EvalString(%q): %v", src, err)
+	}
+	return v
+}
+
+func TestEvalPrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"2 * 3 + 4 * 5", 26.0},
+		{"1 < 2 == true", true},
+		{"10 - 2 - 3", 5.0},
+	}
+	for _, tt := range tests {
+		data := map[string]interface{}{"true": true}
+		if got := eval(t, tt.src, data); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestEvalShortCircuit(t *testing.T) {
+	// "boom" isn't a resolvable method call, so if either operator failed to
+	// short-circuit, evaluating it would return an error.
+	data := map[string]interface{}{"no": false, "yes": true}
+	if got := eval(t, "no && boom.explode()", data); got != false {
+		t.Errorf("no && ... = %v, want false", got)
+	}
+	if got := eval(t, "yes || boom.explode()", data); got != true {
+		t.Errorf("yes || ... = %v, want true", got)
+	}
+}
+
+func TestEvalTernary(t *testing.T) {
+	data := map[string]interface{}{"active": false}
+	if got := eval(t, "active ? 'on' : 'off'", data); got != "off" {
+		t.Errorf("ternary = %v, want off", got)
+	}
+	data["active"] = true
+	if got := eval(t, "active ? 'on' : 'off'", data); got != "on" {
+		t.Errorf("ternary = %v, want on", got)
+	}
+}
+
+func TestEvalMemberAndIndex(t *testing.T) {
+	data := map[string]interface{}{
+		"user":  map[string]interface{}{"name": "bob"},
+		"items": []interface{}{"x", "y", "z"},
+	}
+	if got := eval(t, "user.name", data); got != "bob" {
+		t.Errorf("user.name = %v, want bob", got)
+	}
+	if got := eval(t, "items[1]", data); got != "y" {
+		t.Errorf("items[1] = %v, want y", got)
+	}
+}
+
+func TestEvalMethodCall(t *testing.T) {
+	data := map[string]interface{}{"user": map[string]interface{}{"name": "bob"}}
+	if got := eval(t, "user.name.toUpperCase()", data); got != "BOB" {
+		t.Errorf("toUpperCase() = %v, want BOB", got)
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want bool
+	}{
+		{nil, false},
+		{false, false},
+		{true, true},
+		{0.0, false},
+		{1.0, true},
+		{"", false},
+		{"x", true},
+		{[]interface{}{}, true},
+	}
+	for _, tt := range tests {
+		if got := Truthy(tt.v); got != tt.want {
+			t.Errorf("Truthy(%#v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var errUnterminatedString = errors.New("expr: unterminated string literal")
+
+// binaryPrec lists operators by precedence tier, loosest-binding first, for
+// precedence-climbing parsing.
+var binaryPrec = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "===", "!=="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// parser parses a tokenised expression into an AST.
+type parser struct {
+	lex *lexer
+	cur token
+}
+
+// Parse parses src as an expression.
+func Parse(src string) (Node, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	node, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if p.cur.kind != tokEOF {
+		return nil, fmt.Errorf("expr: unexpected token %q", p.cur.val)
+	}
+	return node, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.cur = tok
+	return nil
+}
+
+func (p *parser) is(val string) bool {
+	return p.cur.kind == tokPunct && p.cur.val == val
+}
+
+func (p *parser) expect(val string) error {
+	if !p.is(val) {
+		return fmt.Errorf("expr: expected %q, got %q", val, p.cur.val)
+	}
+	return p.advance()
+}
+
+// parseTernary parses "cond ? then : else", falling through to binary
+// expressions when there is no "?".
+func (p *parser) parseTernary() (Node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("?") {
+		return cond, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	then, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	return Ternary{Cond: cond, Then: then, Else: els}, nil
+}
+
+// parseBinary implements precedence-climbing over binaryPrec.
+func (p *parser) parseBinary(tier int) (Node, error) {
+	if tier >= len(binaryPrec) {
+		return p.parseUnary()
+	}
+	x, err := p.parseBinary(tier + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.matchAny(binaryPrec[tier])
+		if !ok {
+			return x, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		y, err := p.parseBinary(tier + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = Binary{Op: op, X: x, Y: y}
+	}
+}
+
+func (p *parser) matchAny(ops []string) (string, bool) {
+	if p.cur.kind != tokPunct {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.cur.val == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.is("!") || p.is("-") {
+		op := p.cur.val
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: op, X: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses member access, index access and calls chained onto a
+// primary expression, e.g. "user.name.toUpperCase()" or "items[idx]".
+func (p *parser) parsePostfix() (Node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.is("."):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.cur.kind != tokIdent {
+				return nil, fmt.Errorf("expr: expected property name, got %q", p.cur.val)
+			}
+			prop := p.cur.val
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			x = Member{Object: x, Property: prop}
+		case p.is("["):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			key, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = Index{Object: x, Key: key}
+		case p.is("("):
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			var args []Node
+			for !p.is(")") {
+				arg, err := p.parseTernary()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.is(",") {
+					if err := p.advance(); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			x = Call{Callee: x, Args: args}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	switch {
+	case p.cur.kind == tokIdent:
+		return p.parseIdentLiteral()
+	case p.cur.kind == tokNumber:
+		n, err := strconv.ParseFloat(p.cur.val, 64)
+		if err != nil {
+			return nil, err
+		}
+		return Literal{Value: n}, p.advance()
+	case p.cur.kind == tokString:
+		s := p.cur.val
+		return Literal{Value: s}, p.advance()
+	case p.is("("):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseTernary()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	default:
+		return nil, fmt.Errorf("expr: unexpected token %q", p.cur.val)
+	}
+}
+
+func (p *parser) parseIdentLiteral() (Node, error) {
+	switch p.cur.val {
+	case "true":
+		return Literal{Value: true}, p.advance()
+	case "false":
+		return Literal{Value: false}, p.advance()
+	case "null", "undefined":
+		return Literal{Value: nil}, p.advance()
+	default:
+		name := p.cur.val
+		return Ident{Name: name}, p.advance()
+	}
+}
@@ -0,0 +1,112 @@
+package expr
+
+// tokenKind identifies the lexical class of a token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokPunct
+)
+
+// token is a single lexical token produced by the lexer.
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// lexer tokenises a JS-like expression string.
+type lexer struct {
+	src string
+	pos int
+}
+
+// newLexer creates a lexer over src.
+func newLexer(src string) *lexer {
+	return &lexer{src: src}
+}
+
+// next returns the next token in the input, or a tokEOF token once
+// exhausted.
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case isIdentStart(c):
+		return l.ident(), nil
+	case isDigit(c):
+		return l.number(), nil
+	case c == '\'' || c == '"':
+		return l.string(c)
+	default:
+		return l.punct()
+	}
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t' || l.src[l.pos] == '\n') {
+		l.pos++
+	}
+}
+
+func (l *lexer) ident() token {
+	start := l.pos
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+	return token{kind: tokIdent, val: l.src[start:l.pos]}
+}
+
+func (l *lexer) number() token {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	return token{kind: tokNumber, val: l.src[start:l.pos]}
+}
+
+func (l *lexer) string(quote byte) (token, error) {
+	l.pos++ // opening quote
+	start := l.pos
+	for l.pos < len(l.src) && l.src[l.pos] != quote {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{}, errUnterminatedString
+	}
+	val := l.src[start:l.pos]
+	l.pos++ // closing quote
+	return token{kind: tokString, val: val}, nil
+}
+
+// twoCharPuncts are the multi-character operators recognised by the lexer.
+var twoCharPuncts = []string{"===", "!==", "==", "!=", ">=", "<=", "&&", "||"}
+
+func (l *lexer) punct() (token, error) {
+	for _, p := range twoCharPuncts {
+		if strHasPrefixAt(l.src, l.pos, p) {
+			l.pos += len(p)
+			return token{kind: tokPunct, val: p}, nil
+		}
+	}
+	c := l.src[l.pos]
+	l.pos++
+	return token{kind: tokPunct, val: string(c)}, nil
+}
+
+func strHasPrefixAt(s string, pos int, prefix string) bool {
+	if pos+len(prefix) > len(s) {
+		return false
+	}
+	return s[pos:pos+len(prefix)] == prefix
+}
+
+func isDigit(c byte) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c byte) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isIdentPart(c byte) bool  { return isIdentStart(c) || isDigit(c) }
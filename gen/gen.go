@@ -0,0 +1,136 @@
+// Package gen compiles a component's template HTML into Go source that
+// writes the rendered markup directly, instead of paying the cost of
+// re-parsing the template string and interpreting it on every render.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Options controls how a component template is compiled.
+type Options struct {
+	// Package is the package name written to the generated file.
+	Package string
+	// Struct is the component's Go identifier, used to name the generated
+	// Render<Struct> function.
+	Struct string
+	// NoOptimizeStatic disables collapsing of contiguous static markup into
+	// a single io.WriteString call, which makes the generated output easier
+	// to diff against the source template while debugging the generator.
+	NoOptimizeStatic bool
+}
+
+// Generate compiles tmpl into Go source defining a
+// func Render<Struct>(data map[string]interface{}, w io.Writer) error
+// function, gofmt-normalised.
+func Generate(tmpl string, opts Options) ([]byte, error) {
+	nodes, err := parseFragment(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(nodes); n != 1 {
+		return nil, fmt.Errorf("expected a single root element for template but found: %d", n)
+	}
+	if err := unsupported(nodes[0]); err != nil {
+		return nil, err
+	}
+
+	g := &generator{opts: opts}
+	body := g.node(nodes[0])
+
+	buf := bytes.NewBuffer(nil)
+	err = fileTemplate.Execute(buf, struct {
+		Package string
+		Struct  string
+		Body    string
+	}{opts.Package, opts.Struct, body})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// parseFragment parses a template into html nodes, mirroring vue.parse.
+func parseFragment(tmpl string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(tmpl), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+}
+
+// fileTemplate is the skeleton of a generated file.
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by vue-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+
+	"github.com/ghughes/vue/expr"
+	vuepkg "github.com/ghughes/vue"
+	xhtml "golang.org/x/net/html"
+)
+
+func init() {
+	vuepkg.RegisterRenderer("{{.Struct}}", Render{{.Struct}})
+}
+
+// Render{{.Struct}} renders the {{.Struct}} component directly to w, without
+// interpreting its template at runtime.
+func Render{{.Struct}}(data map[string]interface{}, w io.Writer) error {
+{{.Body}}
+	return nil
+}
+
+// vueGenEval parses and evaluates src as a vue expression against data,
+// routing through the same expr package the interpreter uses so v-if,
+// v-bind, v-for and interpolation expressions evaluate the same whether
+// compiled or interpreted.
+func vueGenEval(data map[string]interface{}, src string) interface{} {
+	v, err := expr.EvalString(src, data)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// vueGenSlice turns v into a []interface{} so a v-for loop can range over
+// it regardless of its concrete slice or array type.
+func vueGenSlice(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// vueGenWriteTag writes an opening tag with the given attributes.
+func vueGenWriteTag(w io.Writer, tag string, attrs []xhtml.Attribute) {
+	io.WriteString(w, "<"+tag)
+	for _, a := range attrs {
+		fmt.Fprintf(w, " %s=\"%s\"", a.Key, html.EscapeString(a.Val))
+	}
+	io.WriteString(w, ">")
+}
+
+// vueGenWriteCloseTag writes a closing tag.
+func vueGenWriteCloseTag(w io.Writer, tag string) {
+	io.WriteString(w, "</"+tag+">")
+}
+`))
@@ -0,0 +1,94 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var attrsDeclRE = regexp.MustCompile(`(vueGenAttrs\d+) := \[\]xhtml\.Attribute`)
+
+func TestGenerateSiblingAttrsDontCollide(t *testing.T) {
+	src, err := Generate(`<div><p>{{ a }}</p><p>{{ b }}</p></div>`, Options{Package: "main", Struct: "Card"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	matches := attrsDeclRE.FindAllStringSubmatch(string(src), -1)
+	if len(matches) < 3 {
+		t.Fatalf("expected at least 3 attrs declarations (div + two p siblings), got %d:\n%s", len(matches), src)
+	}
+	seen := map[string]bool{}
+	for _, m := range matches {
+		name := m[1]
+		if seen[name] {
+			t.Fatalf("attrs variable %s declared more than once, := would fail to compile:\n%s", name, src)
+		}
+		seen[name] = true
+	}
+}
+
+func TestGenerateRoutesExpressionsThroughExpr(t *testing.T) {
+	src, err := Generate(`<p v-if="count &gt; 0">{{ count }}</p>`, Options{Package: "main", Struct: "Card"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(src)
+	if !strings.Contains(out, `expr.Truthy(vueGenEval(data, "count > 0"))`) {
+		t.Fatalf("expected v-if to evaluate \"count > 0\" as an expression, got:\n%s", out)
+	}
+}
+
+func TestGenerateVForSplitsOnInToken(t *testing.T) {
+	src, err := Generate(`<div><p v-for="index in indices">{{ index }}</p></div>`, Options{Package: "main", Struct: "Card"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(src)
+	if !strings.Contains(out, `vueGenEval(data, "indices")`) {
+		t.Fatalf(`expected v-for to split on " in " leaving the collection as "indices", got:\n%s`, out)
+	}
+	if !strings.Contains(out, `data["index"]`) {
+		t.Fatalf(`expected v-for loop variable "index" to survive the split, got:\n%s`, out)
+	}
+}
+
+func TestGenerateDoesNotDoubleEscapeAttributes(t *testing.T) {
+	src, err := Generate(`<p class="c">{{ a }}</p>`, Options{Package: "main", Struct: "Card"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(src)
+	if strings.Contains(out, `=%q`) {
+		t.Fatalf("vueGenWriteTag should quote attributes manually, not with %%q (which re-escapes an already-html-escaped value), got:\n%s", out)
+	}
+}
+
+func TestGenerateRejectsVOn(t *testing.T) {
+	if _, err := Generate(`<button v-on:click="doit">go</button>`, Options{Package: "main", Struct: "Card"}); err == nil {
+		t.Fatal("expected Generate to reject v-on, got nil error")
+	}
+}
+
+func TestGenerateRejectsVModel(t *testing.T) {
+	if _, err := Generate(`<input v-model="name">`, Options{Package: "main", Struct: "Card"}); err == nil {
+		t.Fatal("expected Generate to reject v-model, got nil error")
+	}
+}
+
+func TestGenerateRejectsVShowAndElseChain(t *testing.T) {
+	for _, tmpl := range []string{
+		`<p v-show="ok">hi</p>`,
+		`<div><p v-if="a">a</p><p v-else>b</p></div>`,
+	} {
+		if _, err := Generate(tmpl, Options{Package: "main", Struct: "Card"}); err == nil {
+			t.Fatalf("expected Generate to reject %q, got nil error", tmpl)
+		}
+	}
+}
+
+func TestGenerateRejectsSlot(t *testing.T) {
+	if _, err := Generate(`<div><slot name="header"></slot></div>`, Options{Package: "main", Struct: "Card"}); err == nil {
+		t.Fatal("expected Generate to reject <slot>, got nil error")
+	}
+}
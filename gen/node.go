@@ -0,0 +1,213 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// generator walks a parsed template and emits the Go statements that render
+// it, collapsing contiguous static markup into single writes along the way.
+type generator struct {
+	opts Options
+	tmp  int
+}
+
+// node emits the statements that render node and its siblings, in source
+// order, as a single block of Go source.
+func (g *generator) node(node *html.Node) string {
+	var out strings.Builder
+	for n := node; n != nil; {
+		run, next := g.staticRun(n)
+		if run != "" {
+			fmt.Fprintf(&out, "io.WriteString(w, %s)\n", strconv.Quote(run))
+			n = next
+			continue
+		}
+		out.WriteString(g.dynamic(n))
+		n = n.NextSibling
+	}
+	return out.String()
+}
+
+// staticRun renders n and any immediately following siblings that contain no
+// vue directives or interpolations into a single HTML string, returning the
+// sibling following the run. It renders nothing when n itself is dynamic or
+// NoOptimizeStatic is set.
+func (g *generator) staticRun(n *html.Node) (string, *html.Node) {
+	if g.opts.NoOptimizeStatic || isDynamic(n) {
+		return "", n
+	}
+
+	buf := bytes.NewBuffer(nil)
+	last := n
+	for c := n; c != nil && !isDynamic(c); c = c.NextSibling {
+		if err := html.Render(buf, c); err != nil {
+			return "", n
+		}
+		last = c
+	}
+	return buf.String(), last.NextSibling
+}
+
+// dynamic emits the statements that render a single node containing a vue
+// directive or interpolation somewhere in its subtree.
+func (g *generator) dynamic(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return g.text(n)
+	case html.ElementNode:
+		return g.element(n)
+	default:
+		return ""
+	}
+}
+
+// text emits writes for a text node, splitting it on "{{ }}" interpolations.
+func (g *generator) text(n *html.Node) string {
+	var out strings.Builder
+	rest := n.Data
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start
+
+		if static := rest[:start]; static != "" {
+			fmt.Fprintf(&out, "io.WriteString(w, %s)\n", strconv.Quote(static))
+		}
+		field := strings.TrimSpace(rest[start+2 : end])
+		fmt.Fprintf(&out, "fmt.Fprintf(w, \"%%v\", html.EscapeString(fmt.Sprint(vueGenEval(data, %s))))\n", strconv.Quote(field))
+
+		rest = rest[end+2:]
+	}
+	if rest != "" {
+		fmt.Fprintf(&out, "io.WriteString(w, %s)\n", strconv.Quote(rest))
+	}
+	return out.String()
+}
+
+// element emits the statements for an element, expanding v-if/v-for/v-bind
+// into Go control flow around the tag's attributes, then recursing into its
+// children so nested static runs are still collapsed. As in the interpreter,
+// v-if on a v-for element is evaluated inside the loop, once per item.
+// v-for accepts only the "value in source" form and ranges over slices and
+// arrays (any other value yields no iterations); the loop variable is left
+// set in data after the loop.
+func (g *generator) element(n *html.Node) string {
+	var out strings.Builder
+
+	var vIf, vForVal, vForField string
+	var binds []html.Attribute
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "v-if":
+			vIf = a.Val
+		case a.Key == "v-for":
+			i := strings.LastIndex(a.Val, " in ")
+			vForVal = strings.TrimSpace(a.Val[:i])
+			vForField = strings.TrimSpace(a.Val[i+len(" in "):])
+		case strings.HasPrefix(a.Key, "v-bind:"):
+			binds = append(binds, html.Attribute{Key: strings.TrimPrefix(a.Key, "v-bind:"), Val: a.Val})
+		default:
+			// Plain, non-vue attributes become a static attribute on the
+			// generated tag. v-on and v-model are rejected by unsupported
+			// before element is reached, so they never land here.
+			attrs = append(attrs, a)
+		}
+	}
+
+	if vForField != "" {
+		g.tmp++
+		loopVar := fmt.Sprintf("vueGenElem%d", g.tmp)
+		fmt.Fprintf(&out, "for _, %s := range vueGenSlice(vueGenEval(data, %s)) {\n", loopVar, strconv.Quote(vForField))
+		fmt.Fprintf(&out, "data[%s] = %s\n", strconv.Quote(vForVal), loopVar)
+	}
+	if vIf != "" {
+		fmt.Fprintf(&out, "if expr.Truthy(vueGenEval(data, %s)) {\n", strconv.Quote(vIf))
+	}
+
+	g.tmp++
+	attrsVar := fmt.Sprintf("vueGenAttrs%d", g.tmp)
+
+	fmt.Fprintf(&out, "%s := []xhtml.Attribute{", attrsVar)
+	for _, a := range attrs {
+		fmt.Fprintf(&out, "{Key: %s, Val: %s}, ", strconv.Quote(a.Key), strconv.Quote(a.Val))
+	}
+	out.WriteString("}\n")
+	for _, a := range binds {
+		fmt.Fprintf(&out, "if v, ok := vueGenEval(data, %s).(bool); !ok || v {\n", strconv.Quote(a.Val))
+		fmt.Fprintf(&out, "%s = append(%s, xhtml.Attribute{Key: %s, Val: fmt.Sprintf(\"%%v\", vueGenEval(data, %s))})\n", attrsVar, attrsVar, strconv.Quote(a.Key), strconv.Quote(a.Val))
+		out.WriteString("}\n")
+	}
+	fmt.Fprintf(&out, "vueGenWriteTag(w, %s, %s)\n", strconv.Quote(n.Data), attrsVar)
+	out.WriteString(g.node(n.FirstChild))
+	fmt.Fprintf(&out, "vueGenWriteCloseTag(w, %s)\n", strconv.Quote(n.Data))
+
+	if vIf != "" {
+		out.WriteString("}\n")
+	}
+	if vForField != "" {
+		out.WriteString("}\n")
+	}
+	return out.String()
+}
+
+// unsupported reports whether n or any of its descendants use a vue
+// directive that gen cannot compile, returning an error naming the first one
+// found so Generate can reject the template instead of silently emitting
+// wrong or unimplementable Go.
+func unsupported(n *html.Node) error {
+	if n.Type == html.ElementNode {
+		if n.Data == "slot" {
+			return fmt.Errorf("gen: <slot> is not supported")
+		}
+		for _, a := range n.Attr {
+			switch {
+			case a.Key == "v-model":
+				return fmt.Errorf("gen: v-model is not supported (no access to the component's callback from generated code): %s", n.Data)
+			case strings.HasPrefix(a.Key, "v-on:") || a.Key == "v-on":
+				return fmt.Errorf("gen: v-on is not supported (no access to the component's callback from generated code): %s", n.Data)
+			case a.Key == "v-show" || a.Key == "v-else" || a.Key == "v-else-if":
+				return fmt.Errorf("gen: %s is not supported: %s", a.Key, n.Data)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if err := unsupported(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDynamic reports whether n or any of its descendants carry a vue
+// directive or a "{{ }}" interpolation and therefore need codegen rather
+// than a verbatim HTML write.
+func isDynamic(n *html.Node) bool {
+	if n.Type == html.TextNode && strings.Contains(n.Data, "{{") {
+		return true
+	}
+	if n.Type == html.ElementNode {
+		for _, a := range n.Attr {
+			if strings.HasPrefix(a.Key, "v-") {
+				return true
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isDynamic(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,32 +3,47 @@ package vue
 import (
 	"bytes"
 	"fmt"
-	"github.com/cbroglie/mustache"
+	"github.com/ghughes/vue/expr"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
 	"io"
 	"reflect"
+	"sort"
 	"strings"
 )
 
 const (
-	v      = "v-"
-	vBind  = "v-bind"
-	vFor   = "v-for"
-	vIf    = "v-if"
-	vModel = "v-model"
-	vOn    = "v-on"
+	v       = "v-"
+	vBind   = "v-bind"
+	vElse   = "v-else"
+	vElseIf = "v-else-if"
+	vFor    = "v-for"
+	vIf     = "v-if"
+	vModel  = "v-model"
+	vOn     = "v-on"
+	vShow   = "v-show"
 )
 
-var attrOrder = []string{vFor, vIf, vModel, vOn, vBind}
+var attrOrder = []string{vFor, vIf, vElseIf, vElse, vShow, vModel, vOn, vBind}
 
 type template struct {
 	comp *Comp
 
-	id   int64
 	flag *html.Node
 }
 
+// renderers holds the compiled Render functions produced by the gen
+// package, keyed by component name, and registered via RegisterRenderer
+// from a generated file's init function.
+var renderers = map[string]func(map[string]interface{}, io.Writer) error{}
+
+// RegisterRenderer registers a compiled Render function for the named
+// component, letting the template's execute skip template interpretation
+// entirely.
+func RegisterRenderer(name string, render func(map[string]interface{}, io.Writer) error) {
+	renderers[name] = render
+}
+
 // newTemplate creates a new template.
 func newTemplate(comp *Comp) *template {
 	return &template{comp: comp, flag: &html.Node{}}
@@ -36,6 +51,12 @@ func newTemplate(comp *Comp) *template {
 
 // execute executes the template with the given data to be rendered.
 func (tmpl *template) execute(data map[string]interface{}) []byte {
+	if render, ok := renderers[tmpl.comp.name]; ok {
+		buf := bytes.NewBuffer(nil)
+		must(render(data, buf))
+		return buf.Bytes()
+	}
+
 	buf := bytes.NewBuffer(tmpl.comp.tmpl)
 	nodes := parse(buf)
 	if n := len(nodes); n != 1 {
@@ -43,29 +64,57 @@ func (tmpl *template) execute(data map[string]interface{}) []byte {
 			tmpl.comp.tmpl, n))
 	}
 
-	node := tmpl.executeTraversal(nodes[0], data)
+	node := tmpl.executeTraversal(nodes[0], data, &ifChain{}, nil)
 
 	buf = bytes.NewBuffer(nil)
 	err := html.Render(buf, node)
 	must(err)
 
-	template, err := mustache.ParseString(buf.String())
-	must(err)
+	return buf.Bytes()
+}
 
-	buf.Reset()
-	err = template.FRender(buf, data)
-	must(err)
+// ifChain tracks a run of v-if/v-else-if/v-else siblings as executeTraversal
+// walks a node's children: open reports whether the previous sibling left an
+// if/else-if chain open for a following else-if/else, and matched reports
+// whether any branch seen so far evaluated truthy.
+type ifChain struct {
+	open    bool
+	matched bool
+}
 
-	return buf.Bytes()
+// hasIfFamily reports whether node carries v-if, v-else-if or v-else.
+func hasIfFamily(node *html.Node) bool {
+	for _, attr := range node.Attr {
+		if attr.Key == vIf || attr.Key == vElseIf || attr.Key == vElse {
+			return true
+		}
+	}
+	return false
 }
 
 // executeTraversal recursively traverses the html tree and templates the elements.
-func (tmpl *template) executeTraversal(node *html.Node, data map[string]interface{}) *html.Node {
-	// Leave the text nodes to be rendered.
+// chain carries the v-if/v-else-if/v-else state for node's position among its
+// siblings, skipping over intermediate text nodes. slots holds the parent
+// fragments available to fill this template's <slot> placeholders, keyed by
+// slot name, and is nil while executing the top-level component.
+func (tmpl *template) executeTraversal(node *html.Node, data map[string]interface{}, chain *ifChain, slots map[string][]slotFragment) *html.Node {
+	if node.Type == html.TextNode {
+		node.Data = tmpl.executeText(node.Data, data)
+		return node
+	}
 	if node.Type != html.ElementNode {
 		return node
 	}
 
+	// Any element that isn't itself part of the if/else-if/else chain closes it.
+	if !hasIfFamily(node) {
+		*chain = ifChain{}
+	}
+
+	if node.Data == "slot" {
+		return tmpl.executeSlot(node, data, slots)
+	}
+
 	// Attempt to create a subcomponent from the element.
 	sub, ok := tmpl.comp.newSub(node.Data)
 
@@ -78,7 +127,7 @@ func (tmpl *template) executeTraversal(node *html.Node, data map[string]interfac
 		if strings.HasPrefix(attr.Key, v) {
 			deleteAttr(node, i)
 			i--
-			node = tmpl.executeAttr(node, sub, attr, data)
+			node = tmpl.executeAttr(node, sub, attr, data, chain, slots)
 			// The flag signals that the tree structure was modified.
 			// The next sibling of flag is the node to execute next.
 			if node == tmpl.flag {
@@ -89,8 +138,9 @@ func (tmpl *template) executeTraversal(node *html.Node, data map[string]interfac
 
 	// Execute subcomponent.
 	if ok {
+		subSlots := collectSlots(node, data, tmpl, slots)
 		vm := newViewModel(sub)
-		subNode := vm.subRender()
+		subNode := vm.subRender(subSlots)
 		node.Parent.InsertBefore(subNode, node)
 		node.Parent.RemoveChild(node)
 		// No need to use flag since the subcomponent node is already executed.
@@ -98,20 +148,139 @@ func (tmpl *template) executeTraversal(node *html.Node, data map[string]interfac
 	}
 
 	// Execute children.
-	for child := node.FirstChild; child != nil; child = child.NextSibling {
-		child = tmpl.executeTraversal(child, data)
-	}
-	// The flag must be removed if used, which preserves the expected html structure.
-	// The flag node intentionally fails to execute.
-	if node == tmpl.flag.Parent {
-		node.RemoveChild(tmpl.flag)
+	childChain := &ifChain{}
+	for child := node.FirstChild; child != nil; {
+		executed := tmpl.executeTraversal(child, data, childChain, slots)
+		child = executed.NextSibling
+		// The flag is only needed to preserve the sibling link used above;
+		// it must be detached immediately so it's free to mark the next
+		// skipped sibling, and the flag node intentionally fails to execute.
+		if executed == tmpl.flag {
+			executed.Parent.RemoveChild(executed)
+		}
 	}
 
 	return node
 }
 
+// slotFragment is a chunk of markup written between a subcomponent's open
+// and close tags, waiting to be spliced into one of the subcomponent's
+// <slot> placeholders. It keeps the template, data scope and slots it was
+// written in, since slot content always executes against the parent
+// (subcomponent resolution, v-on/v-model listeners and all), not the child.
+type slotFragment struct {
+	node  *html.Node
+	scope map[string]interface{}
+	tmpl  *template
+	slots map[string][]slotFragment
+}
+
+// collectSlots detaches node's children and buckets them into named slots,
+// keyed by their "slot" attribute ("" for the default slot), so the
+// subcomponent node is about to mount can fill in its <slot> placeholders.
+// tmpl and slots are the parent's own template and incoming slots, recorded
+// alongside scope so the fragment later executes exactly as it would have
+// in place, in the parent's tree.
+func collectSlots(node *html.Node, scope map[string]interface{}, tmpl *template, slots map[string][]slotFragment) map[string][]slotFragment {
+	out := map[string][]slotFragment{}
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		node.RemoveChild(child)
+
+		name := ""
+		if child.Type == html.ElementNode {
+			name = attrValue(child, "slot")
+		}
+		out[name] = append(out[name], slotFragment{node: child, scope: scope, tmpl: tmpl, slots: slots})
+
+		child = next
+	}
+	return out
+}
+
+// executeSlot replaces a <slot> placeholder with the named fragment the
+// parent wrote between this component's tags, executed by the parent's own
+// template in the parent's scope, or with the <slot>'s own children as
+// default content, executed by this template in its own scope, when the
+// parent provided nothing for that name.
+func (tmpl *template) executeSlot(node *html.Node, data map[string]interface{}, slots map[string][]slotFragment) *html.Node {
+	name := attrValue(node, "name")
+
+	node.Parent.InsertBefore(tmpl.flag, node)
+	if fragments, ok := slots[name]; ok && len(fragments) > 0 {
+		for _, frag := range fragments {
+			tmpl.spliceSlotContent(frag.tmpl, frag.node, frag.scope, frag.slots)
+		}
+	} else {
+		for child := node.FirstChild; child != nil; {
+			next := child.NextSibling
+			node.RemoveChild(child)
+			tmpl.spliceSlotContent(tmpl, child, data, slots)
+			child = next
+		}
+	}
+	node.Parent.RemoveChild(node)
+
+	return tmpl.flag
+}
+
+// spliceSlotContent inserts a detached node immediately before tmpl.flag and
+// executes it there in the given scope using exec (the template that owns
+// the content's subcomponents and v-on/v-model listeners, which for
+// parent-supplied fragments is the parent's template, not the receiver).
+// tmpl.flag is detached while node executes, so the content's own v-if,
+// v-for and <slot> elements are free to use it.
+func (tmpl *template) spliceSlotContent(exec *template, node *html.Node, scope map[string]interface{}, slots map[string][]slotFragment) {
+	parent, slot := tmpl.flag.Parent, tmpl.flag.NextSibling
+	parent.RemoveChild(tmpl.flag)
+	parent.InsertBefore(node, slot)
+	if executed := exec.executeTraversal(node, scope, &ifChain{}, slots); executed == exec.flag {
+		executed.Parent.RemoveChild(executed)
+	}
+	parent.InsertBefore(tmpl.flag, slot)
+}
+
+// attrValue returns the value of node's key attribute, removing it, or ""
+// if node has no such attribute.
+func attrValue(node *html.Node, key string) string {
+	for i, attr := range node.Attr {
+		if attr.Key == key {
+			deleteAttr(node, i)
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// executeText evaluates each "{{ expr }}" interpolation found in text
+// against data, substituting the stringified result in place.
+func (tmpl *template) executeText(text string, data map[string]interface{}) string {
+	var out strings.Builder
+	rest := text
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start
+
+		out.WriteString(rest[:start])
+		value, err := expr.EvalString(strings.TrimSpace(rest[start+2:end]), data)
+		must(err)
+		fmt.Fprintf(&out, "%v", value)
+
+		rest = rest[end+2:]
+	}
+	out.WriteString(rest)
+	return out.String()
+}
+
 // executeAttr executes the given vue attribute.
-func (tmpl *template) executeAttr(node *html.Node, sub *Comp, attr html.Attribute, data map[string]interface{}) *html.Node {
+func (tmpl *template) executeAttr(node *html.Node, sub *Comp, attr html.Attribute, data map[string]interface{}, chain *ifChain, slots map[string][]slotFragment) *html.Node {
 	vals := strings.Split(attr.Key, ":")
 	dir, part := vals[0], ""
 	if len(vals) > 1 {
@@ -119,9 +288,15 @@ func (tmpl *template) executeAttr(node *html.Node, sub *Comp, attr html.Attribut
 	}
 	switch dir {
 	case vIf:
-		node = tmpl.executeAttrIf(node, attr.Val, data)
+		node = tmpl.executeAttrIf(node, attr.Val, data, chain)
+	case vElseIf:
+		node = tmpl.executeAttrElseIf(node, attr.Val, data, chain)
+	case vElse:
+		node = tmpl.executeAttrElse(node, chain)
+	case vShow:
+		executeAttrShow(node, attr.Val, data)
 	case vFor:
-		node = tmpl.executeAttrFor(node, attr.Val, data)
+		node = tmpl.executeAttrFor(node, attr.Val, data, slots)
 	case vBind:
 		// break
 		executeAttrBind(node, sub, part, attr.Val, data)
@@ -135,63 +310,201 @@ func (tmpl *template) executeAttr(node *html.Node, sub *Comp, attr html.Attribut
 	return node
 }
 
-// executeAttrIf executes the vue if attribute.
-func (tmpl *template) executeAttrIf(node *html.Node, field string, data map[string]interface{}) *html.Node {
-	if value, ok := data[field]; ok {
-		if val, ok := value.(bool); ok && val {
-			return node
-		}
+// executeAttrIf executes the vue if attribute, opening an if/else-if/else
+// chain that a following v-else-if or v-else sibling can continue.
+func (tmpl *template) executeAttrIf(node *html.Node, expression string, data map[string]interface{}, chain *ifChain) *html.Node {
+	matched := expr.Truthy(mustEval(expression, data))
+	*chain = ifChain{open: true, matched: matched}
+	if matched {
+		return node
 	}
 	node.Parent.InsertBefore(tmpl.flag, node)
 	node.Parent.RemoveChild(node)
 	return tmpl.flag
 }
 
-// executeAttrFor executes the vue for attribute.
-func (tmpl *template) executeAttrFor(node *html.Node, value string, data map[string]interface{}) *html.Node {
-	vals := strings.Split(value, "in")
-	name := bytes.TrimSpace([]byte(vals[0]))
-	field := strings.TrimSpace(vals[1])
+// executeAttrElseIf executes the vue else-if attribute, rendering the
+// element only if it is part of an open chain and no earlier branch matched.
+func (tmpl *template) executeAttrElseIf(node *html.Node, expression string, data map[string]interface{}, chain *ifChain) *html.Node {
+	if !chain.open {
+		must(fmt.Errorf("v-else-if used without a preceding v-if"))
+	}
 
-	slice, ok := data[field]
-	if !ok {
-		must(fmt.Errorf("slice not found for field: %s", field))
+	matched := !chain.matched && expr.Truthy(mustEval(expression, data))
+	chain.matched = chain.matched || matched
+	if matched {
+		return node
 	}
+	node.Parent.InsertBefore(tmpl.flag, node)
+	node.Parent.RemoveChild(node)
+	return tmpl.flag
+}
 
-	elem := bytes.NewBuffer(nil)
-	err := html.Render(elem, node)
+// executeAttrElse executes the vue else attribute, rendering the element
+// only if no earlier branch of the chain matched, and closing the chain.
+func (tmpl *template) executeAttrElse(node *html.Node, chain *ifChain) *html.Node {
+	if !chain.open {
+		must(fmt.Errorf("v-else used without a preceding v-if"))
+	}
+
+	matched := !chain.matched
+	*chain = ifChain{}
+	if matched {
+		return node
+	}
+	node.Parent.InsertBefore(tmpl.flag, node)
+	node.Parent.RemoveChild(node)
+	return tmpl.flag
+}
+
+// executeAttrShow executes the vue show attribute, which unlike v-if keeps
+// the element (and any subcomponent it mounts) in the tree, toggling
+// visibility with a "display:none" style instead of unmounting it.
+func executeAttrShow(node *html.Node, expression string, data map[string]interface{}) {
+	if expr.Truthy(mustEval(expression, data)) {
+		return
+	}
+	for i, attr := range node.Attr {
+		if attr.Key != "style" {
+			continue
+		}
+		style := strings.TrimSpace(attr.Val)
+		if style != "" && !strings.HasSuffix(style, ";") {
+			style += ";"
+		}
+		node.Attr[i].Val = style + "display:none"
+		return
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "style", Val: "display:none"})
+}
+
+// mustEval evaluates expression against data, panicking via must on error.
+func mustEval(expression string, data map[string]interface{}) interface{} {
+	value, err := expr.EvalString(expression, data)
 	must(err)
+	return value
+}
 
-	buf := bytes.NewBuffer(nil)
-	values := reflect.ValueOf(slice)
-	n := values.Len()
-	for i := 0; i < n; i++ {
-		key := fmt.Sprintf("%s%d", name, tmpl.id)
-		tmpl.id++
+// executeAttrFor executes the vue for attribute, accepting "value in field",
+// "(value, key) in field" and "(value, key, index) in field".
+func (tmpl *template) executeAttrFor(node *html.Node, value string, data map[string]interface{}, slots map[string][]slotFragment) *html.Node {
+	names, source := parseForClause(value)
 
-		b := bytes.Replace(elem.Bytes(), name, []byte(key), -1)
-		_, err := buf.Write(b)
-		must(err)
+	collection, err := expr.EvalString(source, data)
+	must(err)
 
-		data[key] = values.Index(i).Interface()
-	}
+	elem := bytes.NewBuffer(nil)
+	err = html.Render(elem, node)
+	must(err)
 
-	nodes := parse(buf)
 	node.Parent.InsertBefore(tmpl.flag, node)
-	for _, child := range nodes {
-		node.Parent.InsertBefore(child, node)
+	for _, entry := range iterate(collection) {
+		scope := entry.scope(names)
+		prev := map[string]interface{}{}
+		hadPrev := map[string]bool{}
+		for k, v := range scope {
+			prev[k], hadPrev[k] = data[k]
+			data[k] = v
+		}
+
+		nodes := parse(bytes.NewReader(elem.Bytes()))
+		chain := &ifChain{}
+		for _, child := range nodes {
+			// Each copy executes in place before node, with the flag detached
+			// meanwhile so the copy's own v-if, v-for and <slot> can use it;
+			// the flag then goes back right before node, after the copies.
+			node.Parent.RemoveChild(tmpl.flag)
+			node.Parent.InsertBefore(child, node)
+			if executed := tmpl.executeTraversal(child, data, chain, slots); executed == tmpl.flag {
+				executed.Parent.RemoveChild(executed)
+			}
+			node.Parent.InsertBefore(tmpl.flag, node)
+		}
+
+		for k := range scope {
+			if hadPrev[k] {
+				data[k] = prev[k]
+			} else {
+				delete(data, k)
+			}
+		}
 	}
 	node.Parent.RemoveChild(node)
 
 	return tmpl.flag
 }
 
+// forEntry is a single step of a v-for iteration: a value paired with its
+// key (array index or map key) and its sequential index.
+type forEntry struct {
+	value, key, index interface{}
+}
+
+// scope maps a forEntry onto the "(value, key, index)" names bound by the
+// v-for expression, omitting names that weren't requested.
+func (e forEntry) scope(names []string) map[string]interface{} {
+	scope := map[string]interface{}{names[0]: e.value}
+	if len(names) > 1 {
+		scope[names[1]] = e.key
+	}
+	if len(names) > 2 {
+		scope[names[2]] = e.index
+	}
+	return scope
+}
+
+// iterate walks a slice, array or map, yielding a forEntry per element.
+// Map keys are visited sorted by their fmt.Sprint form so rendering is
+// deterministic.
+func iterate(collection interface{}) []forEntry {
+	values := reflect.ValueOf(collection)
+	switch values.Kind() {
+	case reflect.Slice, reflect.Array:
+		entries := make([]forEntry, values.Len())
+		for i := range entries {
+			entries[i] = forEntry{value: values.Index(i).Interface(), key: i, index: i}
+		}
+		return entries
+	case reflect.Map:
+		keys := values.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		entries := make([]forEntry, len(keys))
+		for i, k := range keys {
+			entries[i] = forEntry{value: values.MapIndex(k).Interface(), key: k.Interface(), index: i}
+		}
+		return entries
+	default:
+		must(fmt.Errorf("v-for: cannot iterate over %T", collection))
+		return nil
+	}
+}
+
+// parseForClause splits a v-for expression into its bound names and source
+// expression, e.g. "(item, idx) in items" becomes (["item", "idx"], "items").
+func parseForClause(value string) ([]string, string) {
+	i := strings.LastIndex(value, " in ")
+	if i < 0 {
+		must(fmt.Errorf("v-for: expected \"... in ...\", got: %s", value))
+	}
+	left := strings.TrimSpace(value[:i])
+	source := strings.TrimSpace(value[i+len(" in "):])
+
+	left = strings.TrimPrefix(left, "(")
+	left = strings.TrimSuffix(left, ")")
+
+	var names []string
+	for _, name := range strings.Split(left, ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names, source
+}
+
 // executeAttrBind executes the vue bind attribute.
 func executeAttrBind(node *html.Node, sub *Comp, key, value string, data map[string]interface{}) {
-	field, ok := data[value]
-	if !ok {
-		must(fmt.Errorf("unknown data field: %s", value))
-	}
+	field, err := expr.EvalString(value, data)
+	must(err)
 
 	prop := strings.Title(key)
 	if sub.hasProp(prop) {
@@ -247,17 +560,21 @@ func orderAttrs(node *html.Node) {
 	if n == 0 {
 		return
 	}
+	taken := make([]bool, n)
 	attrs := make([]html.Attribute, 0, n)
 	for _, prefix := range attrOrder {
-		for _, attr := range node.Attr {
-			if strings.HasPrefix(attr.Key, prefix) {
+		for i, attr := range node.Attr {
+			// v-else-if is itself prefixed by v-else, so each attribute may
+			// only be claimed by the first matching prefix.
+			if !taken[i] && strings.HasPrefix(attr.Key, prefix) {
 				attrs = append(attrs, attr)
+				taken[i] = true
 			}
 		}
 	}
 	// Append other attributes which are not vue attributes.
-	for _, attr := range node.Attr {
-		if !strings.HasPrefix(attr.Key, v) {
+	for i, attr := range node.Attr {
+		if !taken[i] && !strings.HasPrefix(attr.Key, v) {
 			attrs = append(attrs, attr)
 		}
 	}
@@ -268,4 +585,4 @@ func orderAttrs(node *html.Node) {
 // Attribute order is preserved.
 func deleteAttr(node *html.Node, i int) {
 	node.Attr = append(node.Attr[:i], node.Attr[i+1:]...)
-}
\ No newline at end of file
+}
@@ -0,0 +1,167 @@
+package vue
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// parseOne parses src as a single root element, for use by tests that only
+// need a node tree and not a full template execution.
+func parseOne(t *testing.T, src string) *html.Node {
+	t.Helper()
+	nodes := parse(strings.NewReader(src))
+	if len(nodes) != 1 {
+		t.Fatalf("parseOne(%q): expected a single root element, got %d", src, len(nodes))
+	}
+	return nodes[0]
+}
+
+func TestHasIfFamily(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`<p v-if="a">x</p>`, true},
+		{`<p v-else-if="a">x</p>`, true},
+		{`<p v-else>x</p>`, true},
+		{`<p v-show="a">x</p>`, false},
+		{`<p>x</p>`, false},
+	}
+	for _, tt := range tests {
+		if got := hasIfFamily(parseOne(t, tt.src)); got != tt.want {
+			t.Errorf("hasIfFamily(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+// runIfElseChain walks <div><p v-if="a">a</p><p v-else-if="b">b</p>
+// <p v-else>c</p></div> one sibling at a time, the way executeTraversal
+// does, and returns the text of whichever branch rendered.
+func runIfElseChain(t *testing.T, tmpl *template, data map[string]interface{}) string {
+	t.Helper()
+	root := parseOne(t, `<div><p v-if="a">a</p><p v-else-if="b">b</p><p v-else>c</p></div>`)
+	chain := &ifChain{}
+	var rendered []string
+	for child := root.FirstChild; child != nil; {
+		next := child.NextSibling
+		result := execIfFamily(t, tmpl, child, data, chain)
+		if result == tmpl.flag {
+			result.Parent.RemoveChild(result)
+		} else {
+			rendered = append(rendered, text(result))
+		}
+		child = next
+	}
+	return strings.Join(rendered, ",")
+}
+
+func TestIfElseChain(t *testing.T) {
+	tmpl := &template{flag: &html.Node{}}
+
+	if got := runIfElseChain(t, tmpl, map[string]interface{}{"a": true, "b": true}); got != "a" {
+		t.Errorf("a=true,b=true: got %q, want \"a\"", got)
+	}
+	if got := runIfElseChain(t, tmpl, map[string]interface{}{"a": false, "b": true}); got != "b" {
+		t.Errorf("a=false,b=true: got %q, want \"b\"", got)
+	}
+	if got := runIfElseChain(t, tmpl, map[string]interface{}{"a": false, "b": false}); got != "c" {
+		t.Errorf("a=false,b=false: got %q, want \"c\"", got)
+	}
+}
+
+// hasAttr reports whether node carries the named attribute, without
+// consuming it (unlike attrValue).
+func hasAttr(node *html.Node, key string) bool {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// execIfFamily dispatches a v-if/v-else-if/v-else node to the matching
+// execute* function, mirroring the dispatch executeAttr does.
+func execIfFamily(t *testing.T, tmpl *template, node *html.Node, data map[string]interface{}, chain *ifChain) *html.Node {
+	t.Helper()
+	switch {
+	case hasAttr(node, "v-if"):
+		return tmpl.executeAttrIf(node, attrValue(node, "v-if"), data, chain)
+	case hasAttr(node, "v-else-if"):
+		return tmpl.executeAttrElseIf(node, attrValue(node, "v-else-if"), data, chain)
+	case hasAttr(node, "v-else"):
+		return tmpl.executeAttrElse(node, chain)
+	default:
+		t.Fatalf("node has no if-family attribute")
+		return nil
+	}
+}
+
+// text returns a node's first text child's data, for asserting which branch
+// of an if/else-if/else chain rendered.
+func text(node *html.Node) string {
+	if node.FirstChild != nil && node.FirstChild.Type == html.TextNode {
+		return node.FirstChild.Data
+	}
+	return ""
+}
+
+func TestExecuteAttrShowMergesExistingStyle(t *testing.T) {
+	node := parseOne(t, `<p style="color:red">hi</p>`)
+	executeAttrShow(node, "ok", map[string]interface{}{"ok": false})
+
+	var styles []string
+	for _, a := range node.Attr {
+		if a.Key == "style" {
+			styles = append(styles, a.Val)
+		}
+	}
+	if len(styles) != 1 {
+		t.Fatalf("expected exactly one style attribute, got %v", styles)
+	}
+	if !strings.Contains(styles[0], "color:red") || !strings.Contains(styles[0], "display:none") {
+		t.Errorf("expected merged style, got %q", styles[0])
+	}
+}
+
+func TestExecuteAttrShowTrueLeavesNodeUntouched(t *testing.T) {
+	node := parseOne(t, `<p style="color:red">hi</p>`)
+	executeAttrShow(node, "ok", map[string]interface{}{"ok": true})
+
+	for _, a := range node.Attr {
+		if a.Key == "style" && a.Val != "color:red" {
+			t.Errorf("expected style to be untouched, got %q", a.Val)
+		}
+	}
+}
+
+func TestCollectSlotsBucketsByName(t *testing.T) {
+	root := parseOne(t, `<my-card><h1 slot="header">Hi</h1><p>body</p><p slot="header">Again</p></my-card>`)
+	scope := map[string]interface{}{"x": 1}
+	parent := &template{flag: &html.Node{}}
+	slots := collectSlots(root, scope, parent, nil)
+
+	if len(slots[""]) != 1 {
+		t.Fatalf("expected 1 default-slot fragment, got %d", len(slots[""]))
+	}
+	if len(slots["header"]) != 2 {
+		t.Fatalf("expected 2 \"header\"-slot fragments, got %d", len(slots["header"]))
+	}
+	for name, frags := range slots {
+		for _, f := range frags {
+			if f.tmpl != parent {
+				t.Errorf("slot %q fragment recorded the wrong template", name)
+			}
+			if f.scope["x"] != 1 {
+				t.Errorf("slot %q fragment recorded the wrong scope", name)
+			}
+		}
+	}
+
+	// collectSlots must detach the children from root.
+	if root.FirstChild != nil {
+		t.Errorf("expected root to have no children left after collectSlots, got %v", root.FirstChild)
+	}
+}